@@ -0,0 +1,99 @@
+// Package client provides lightweight Go helpers for interacting with a
+// running AgentFlow server, mirroring the REST/SSE surface exposed under
+// /api/v1.
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/aor"
+)
+
+// StreamOptions configures StreamEvents.
+type StreamOptions struct {
+	// RunID scopes the stream to a single WorkflowRun. If empty, events
+	// for every run are streamed.
+	RunID string
+	// Workflow is sent as ?workflow=. The server does not filter by
+	// workflow: without RunID the request is rejected with 400, so
+	// StreamEvents returns an error; with RunID the parameter is ignored.
+	Workflow string
+	// Types filters events by category ("run", "step", "signal"), derived
+	// from each event's Type. It is applied only when RunID is empty; the
+	// per-run stream ignores it.
+	Types []string
+	// Past replays the run's events stored by the server before tailing
+	// live ones. It is applied only when RunID is set; the all-runs
+	// stream ignores it and streams live events only.
+	Past bool
+}
+
+// StreamEvents subscribes to the AgentFlow event stream and delivers each
+// decoded aor.Event on ch until ctx is canceled or the connection is
+// closed. Callers are responsible for closing ch's consumer side; this
+// function closes ch before returning.
+func StreamEvents(ctx context.Context, baseURL string, opts StreamOptions, ch chan<- *aor.Event) error {
+	defer close(ch)
+
+	endpoint := baseURL + "/api/v1/events"
+	if opts.RunID != "" {
+		endpoint = fmt.Sprintf("%s/api/v1/runs/%s/events", baseURL, opts.RunID)
+	}
+
+	q := url.Values{}
+	if opts.Workflow != "" {
+		q.Set("workflow", opts.Workflow)
+	}
+	if len(opts.Types) > 0 {
+		q.Set("types", strings.Join(opts.Types, ","))
+	}
+	if opts.Past {
+		q.Set("past", "true")
+	}
+	if encoded := q.Encode(); encoded != "" {
+		endpoint = endpoint + "?" + encoded
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to open event stream: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("event stream returned status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var evt aor.Event
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &evt); err != nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ch <- &evt:
+		}
+	}
+
+	return scanner.Err()
+}
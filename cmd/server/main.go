@@ -1,29 +1,44 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/aor"
 	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/cas"
 	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/common"
+	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/config"
 	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/pop"
 	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/scl"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 var (
-	port    = flag.String("port", "8080", "HTTP port to listen on")
-	dbURL   = flag.String("db", "", "Database connection URL")
-	debug   = flag.Bool("debug", false, "Enable debug mode")
+	port            = flag.String("port", "8080", "HTTP port to listen on")
+	dbURL           = flag.String("db", "", "Database connection URL")
+	natsURL         = flag.String("nats", "nats://localhost:4222", "NATS server URL")
+	redisURL        = flag.String("redis", "localhost:6379", "Redis address")
+	debug           = flag.Bool("debug", false, "Enable debug mode")
+	shutdownTimeout = flag.Duration("shutdown-timeout", 30*time.Second, "Time to wait for in-flight work to drain before forcing exit")
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(os.Args[2:])
+		return
+	}
+
 	flag.Parse()
 
 	if *dbURL == "" {
@@ -33,70 +48,204 @@ func main() {
 		}
 	}
 
-	// Initialize database
-	db, err := gorm.Open(postgres.Open(*dbURL), &gorm.Config{})
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{URL: *dbURL},
+		Redis:    config.RedisConfig{Host: *redisURL},
+		NATS:     config.NATSConfig{URL: *natsURL},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if !*debug {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// The HTTP server starts listening immediately, before the blocking
+	// control-plane/worker/gorm initialization below, so /health is
+	// actually reachable (and reports 503 "starting") while an
+	// orchestrator is waiting on Postgres to come up. activeHandler is
+	// swapped atomically once the full router is ready, so there's no
+	// window where the listener is up but routing to nothing.
+	var activeHandler atomic.Value
+	activeHandler.Store(startingHandler())
+
+	addr := fmt.Sprintf(":%s", *port)
+	httpServer := &http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			activeHandler.Load().(http.Handler).ServeHTTP(w, r)
+		}),
+	}
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		log.Printf("Starting AgentFlow server on %s", addr)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			return fmt.Errorf("HTTP server error: %w", err)
+		}
+		return nil
+	})
+
+	// The control plane owns event publication (NATS + workflow_event
+	// backfill) for the streaming endpoints below; it runs alongside the
+	// request/response Service rather than replacing it. NewControlPlane
+	// blocks, retrying with backoff, until Postgres is reachable so the
+	// server doesn't have to race the database container on startup.
+	controlPlane, err := aor.NewControlPlane(ctx, cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize control plane: %v", err)
+	}
+	if err := controlPlane.Start(ctx); err != nil {
+		log.Fatalf("Failed to start control plane: %v", err)
+	}
+
+	// The server embeds a worker so a single binary can run end to end in
+	// development; production deployments run cmd/worker separately.
+	worker, err := aor.NewWorker(ctx, cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Failed to initialize worker: %v", err)
+	}
+	if err := worker.Start(ctx); err != nil {
+		log.Fatalf("Failed to start worker: %v", err)
 	}
 
-	// Auto-migrate database schema
-	if err := migrateDatabase(db); err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+	// gorm still backs the request/response Service layer (aor, pop, scl);
+	// the control plane above has already waited for Postgres to come up,
+	// so this dials a database we know is reachable. Schema changes are
+	// applied ahead of time via `agentflow migrate up`, not here.
+	gormDB, err := gorm.Open(postgres.Open(*dbURL), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
 	// Initialize services
-	aorService := aor.NewService(db)
-	popService := pop.NewService(db)
-	sclService := scl.NewService(db)
+	aorService := aor.NewService(gormDB)
+	popService := pop.NewService(gormDB)
+	sclService := scl.NewService(gormDB)
 
-	// Setup HTTP router
-	if !*debug {
-		gin.SetMode(gin.ReleaseMode)
-	}
-	
 	router := gin.Default()
-	
+
 	// Serve static files for web dashboard
 	router.Static("/static", "./web/static")
 	router.GET("/", func(c *gin.Context) {
 		c.Redirect(http.StatusMovedPermanently, "/static/index.html")
 	})
-	
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		// Check database connection
-		sqlDB, err := db.DB()
-		dbStatus := "ok"
-		if err != nil || sqlDB.Ping() != nil {
-			dbStatus = "error"
-		}
 
-		status := gin.H{
-			"status":   "ok",
-			"database": dbStatus,
-			"queue":    "ok", // TODO: Check NATS connection
-			"workers":  0,    // TODO: Count active workers
-		}
+	// Health check. Reports "starting" (503) while Postgres hasn't come up
+	// yet, "degraded" once Postgres is up but NATS or Redis isn't, and
+	// "ok" once every dependency the control plane holds is reachable.
+	router.GET("/health", func(c *gin.Context) {
+		status, dbOK, queueOK, redisOK := controlPlane.Health(c.Request.Context())
 
-		if dbStatus == "error" {
-			status["status"] = "degraded"
+		httpStatus := http.StatusOK
+		if status == aor.HealthStarting {
+			httpStatus = http.StatusServiceUnavailable
 		}
 
-		c.JSON(http.StatusOK, status)
+		c.JSON(httpStatus, gin.H{
+			"status":   status,
+			"database": boolStatus(dbOK),
+			"queue":    boolStatus(queueOK),
+			"redis":    boolStatus(redisOK),
+		})
 	})
 
 	// Setup API routes
-	setupAORRoutes(router, aorService)
+	setupAORRoutes(router, aorService, controlPlane)
 	setupPOPRoutes(router, popService)
 	setupSCLRoutes(router, sclService)
 
-	// Start server
-	addr := fmt.Sprintf(":%s", *port)
-	log.Printf("Starting AgentFlow server on %s", addr)
-	
-	if err := router.Run(addr); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	activeHandler.Store(http.Handler(router))
+
+	g.Go(func() error {
+		<-gCtx.Done()
+		return shutdown(httpServer, controlPlane, worker, gormDB)
+	})
+
+	if err := g.Wait(); err != nil {
+		log.Fatalf("Server exited with error: %v", err)
+	}
+}
+
+// startingHandler serves /health (reporting 503 "starting") and 503 for
+// everything else, so the HTTP server has something to answer requests
+// with for the window between ListenAndServe and the full router being
+// assembled once the control plane and worker have connected to Postgres
+// and NATS. Redis reachability is not checked before the swap.
+func startingHandler() http.Handler {
+	router := gin.New()
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": aor.HealthStarting})
+	})
+	router.NoRoute(func(c *gin.Context) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": aor.HealthStarting})
+	})
+	return router
+}
+
+// shutdown drains the HTTP server, worker, and control plane in order,
+// logging which subsystem is still draining every second so operators
+// can diagnose a stuck shutdown, closes the gorm DB backing the
+// request/response services, and gives up after shutdownTimeout.
+func shutdown(httpServer *http.Server, controlPlane *aor.ControlPlane, worker *aor.Worker, gormDB *gorm.DB) error {
+	log.Println("Shutdown signal received, draining...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+
+	stages := []struct {
+		name string
+		fn   func(context.Context) error
+	}{
+		{"http server", httpServer.Shutdown},
+		{"worker", worker.Shutdown},
+		{"control plane", controlPlane.Shutdown},
+		{"gorm db", func(context.Context) error {
+			sqlDB, err := gormDB.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		}},
 	}
+
+	for _, stage := range stages {
+		done := make(chan error, 1)
+		go func(fn func(context.Context) error) { done <- fn(ctx) }(stage.fn)
+
+		ticker := time.NewTicker(1 * time.Second)
+	drain:
+		for {
+			select {
+			case err := <-done:
+				ticker.Stop()
+				if err != nil {
+					log.Printf("%s shutdown returned an error: %v", stage.name, err)
+				}
+				break drain
+			case <-ticker.C:
+				log.Printf("Still draining %s...", stage.name)
+			case <-ctx.Done():
+				ticker.Stop()
+				return fmt.Errorf("shutdown timed out after %s while draining %s", *shutdownTimeout, stage.name)
+			}
+		}
+	}
+
+	log.Println("Shutdown complete")
+	return nil
+}
+
+// boolStatus renders a dependency check as the "ok"/"error" strings the
+// original /health handler used, so existing consumers of this field
+// don't need to change.
+func boolStatus(ok bool) string {
+	if ok {
+		return "ok"
+	}
+	return "error"
 }
 
 func migrateDatabase(db *gorm.DB) error {
@@ -115,22 +264,38 @@ func migrateDatabase(db *gorm.DB) error {
 	)
 }
 
-func setupAORRoutes(router *gin.Engine, service *aor.Service) {
+func setupAORRoutes(router *gin.Engine, service *aor.Service, controlPlane *aor.ControlPlane) {
 	v1 := router.Group("/api/v1")
-	
+
 	// Workflow management
 	v1.POST("/workflows/:name/versions", service.CreateWorkflowSpec)
 	v1.GET("/workflows/:name/versions/:version", service.GetWorkflowSpec)
 	v1.GET("/workflows/:name", service.GetLatestWorkflowSpec)
 	v1.GET("/workflows", service.ListWorkflows)
-	
+
 	// Run management
 	v1.POST("/runs", service.CreateRun)
 	v1.GET("/runs/:id", service.GetRun)
 	v1.GET("/runs", service.ListRuns)
 	v1.POST("/runs/:id/cancel", service.CancelRun)
 	v1.POST("/signals/:run_id", service.SendSignal)
-	
+
+	// Event streaming
+	v1.GET("/runs/:id/events", controlPlane.StreamRunEvents)
+	v1.GET("/events", controlPlane.StreamEvents)
+
+	// Run inspection
+	v1.GET("/runs/:id/graph", controlPlane.GetRunGraph)
+	v1.GET("/runs/:id/steps/:step_id/logs", controlPlane.GetStepLogs)
+	v1.POST("/runs/:id/steps/:step_id/rerun", controlPlane.RerunStep)
+	v1.POST("/runs/:id/approve", controlPlane.ApproveRun)
+
+	// Schedules
+	v1.POST("/workflows/:name/schedules", controlPlane.CreateSchedule)
+	v1.GET("/schedules/:id", controlPlane.GetSchedule)
+	v1.DELETE("/schedules/:id", controlPlane.DeleteSchedule)
+	v1.POST("/schedules/:id/backfill", controlPlane.BackfillSchedule)
+
 	// Worker APIs
 	v1.POST("/tasks/heartbeat", service.WorkerHeartbeat)
 	v1.POST("/tasks/complete", service.CompleteTask)
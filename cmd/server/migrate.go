@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/config"
+	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/db"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// migrationLockKey is the pg_try_advisory_lock key guarding AutoMigrate
+// so two migrate invocations started concurrently can't race on DDL. The
+// server itself no longer migrates and never takes this lock.
+const migrationLockKey = 8417_2026
+
+// runMigrateCommand implements `agentflow migrate up`, which used to run
+// implicitly on every server start. Splitting it out means a deploy
+// controls when schema changes happen instead of racing AutoMigrate
+// against every replica's startup.
+func runMigrateCommand(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dbURL := fs.String("db", os.Getenv("DATABASE_URL"), "Database connection URL")
+	fs.Parse(args)
+
+	if len(fs.Args()) != 1 || fs.Args()[0] != "up" {
+		log.Fatal("usage: agentflow migrate up")
+	}
+
+	if *dbURL == "" {
+		log.Fatal("DATABASE_URL must be set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+
+	pgDB, err := db.WaitForReady(ctx, &config.DatabaseConfig{URL: *dbURL}, db.DefaultBackoff)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer pgDB.Close()
+
+	acquired, err := pgDB.TryAdvisoryLock(ctx, migrationLockKey)
+	if err != nil {
+		log.Fatalf("Failed to acquire migration lock: %v", err)
+	}
+	if !acquired {
+		log.Fatal("another process is already running migrations")
+	}
+	defer pgDB.AdvisoryUnlock(ctx, migrationLockKey)
+
+	gormDB, err := gorm.Open(postgres.Open(*dbURL), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	if err := migrateDatabase(gormDB); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	log.Println("Migrations applied")
+}
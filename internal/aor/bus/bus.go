@@ -0,0 +1,119 @@
+// Package bus centralizes the NATS JetStream stream and consumer
+// configuration shared by ControlPlane and Worker, so dispatch topology
+// (stream names, subjects, retention, per-priority backpressure) lives in
+// one place instead of being duplicated across the two.
+package bus
+
+import (
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Priority is a task queue priority tier. Workers run one pull-consumer
+// fetcher goroutine per priority. Gold has execution slots of its own
+// plus access to a pool shared with Silver and Bronze; Silver and Bronze
+// contend for that shared pool on equal terms.
+type Priority string
+
+const (
+	PriorityGold   Priority = "gold"
+	PrioritySilver Priority = "silver"
+	PriorityBronze Priority = "bronze"
+)
+
+// Priorities lists every tier in dispatch precedence order.
+var Priorities = []Priority{PriorityGold, PrioritySilver, PriorityBronze}
+
+const (
+	StreamTasks   = "AGENTFLOW_TASKS"
+	StreamResults = "AGENTFLOW_RESULTS"
+	StreamSignals = "AGENTFLOW_SIGNALS"
+	StreamEvents  = "AGENTFLOW_EVENTS"
+	StreamDLQ     = "AGENTFLOW_DLQ"
+)
+
+// TaskSubject returns the subject tasks of the given priority are
+// published to, e.g. agentflow.tasks.gold.
+func TaskSubject(p Priority) string {
+	return fmt.Sprintf("agentflow.tasks.%s", p)
+}
+
+// ResultSubject returns the per-run subject results are published to, so
+// a subscriber that only cares about one run can filter cheaply instead
+// of scanning every result in the stream.
+func ResultSubject(runID string) string {
+	return fmt.Sprintf("agentflow.results.%s", runID)
+}
+
+// DLQSubject returns the subject a poison message for task is
+// republished to after exhausting MaxDeliver attempts.
+func DLQSubject(priority Priority) string {
+	return fmt.Sprintf("agentflow.dlq.%s", priority)
+}
+
+// StreamConfigs returns the JetStream stream definitions AgentFlow
+// depends on. Tasks use work-queue retention (each message is delivered
+// to exactly one consumer and removed once acked); results, signals, and
+// events are fanned out to every interested subscriber instead.
+func StreamConfigs() []*nats.StreamConfig {
+	return []*nats.StreamConfig{
+		{
+			Name:      StreamTasks,
+			Subjects:  []string{"agentflow.tasks.*"},
+			Retention: nats.WorkQueuePolicy,
+		},
+		{
+			Name:     StreamResults,
+			Subjects: []string{"agentflow.results.*"},
+		},
+		{
+			Name:     StreamSignals,
+			Subjects: []string{"agentflow.signals"},
+		},
+		{
+			Name:     StreamEvents,
+			Subjects: []string{"agentflow.events.>"},
+		},
+		{
+			Name:      StreamDLQ,
+			Subjects:  []string{"agentflow.dlq.*"},
+			Retention: nats.WorkQueuePolicy,
+		},
+	}
+}
+
+// MaxAckPendingByPriority caps how many unacked task deliveries a
+// priority's pull consumer may have outstanding at once. Gold gets the
+// deepest window so it can keep saturating workers even while Silver and
+// Bronze are still draining their own backlogs.
+var MaxAckPendingByPriority = map[Priority]int{
+	PriorityGold:   256,
+	PrioritySilver: 64,
+	PriorityBronze: 16,
+}
+
+// TaskConsumerConfig returns the durable pull-consumer config for a
+// priority's task subject. The consumer is shared by every worker
+// process (not one per worker instance), so restarts don't leave
+// orphaned durables behind.
+func TaskConsumerConfig(p Priority) *nats.ConsumerConfig {
+	return &nats.ConsumerConfig{
+		Durable:       fmt.Sprintf("worker-%s", p),
+		AckPolicy:     nats.AckExplicitPolicy,
+		MaxAckPending: MaxAckPendingByPriority[p],
+		MaxDeliver:    5,
+		FilterSubject: TaskSubject(p),
+	}
+}
+
+// ResultConsumerConfig returns the durable pull-consumer config for the
+// ResultReducer, which is the single writer of workflow_run aggregates.
+func ResultConsumerConfig() *nats.ConsumerConfig {
+	return &nats.ConsumerConfig{
+		Durable:       "result-reducer",
+		AckPolicy:     nats.AckExplicitPolicy,
+		MaxAckPending: 512,
+		FilterSubject: "agentflow.results.*",
+	}
+}
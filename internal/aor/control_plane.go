@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/aor/bus"
 	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/config"
 	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/db"
 	"github.com/nats-io/nats.go"
@@ -22,17 +23,24 @@ type ControlPlane struct {
 	nats     *nats.Conn
 	js       nats.JetStreamContext
 	
-	scheduler *Scheduler
-	monitor   *Monitor
+	scheduler      *Scheduler
+	monitor        *Monitor
+	scheduleRunner *ScheduleRunner
+	resultReducer  *ResultReducer
 	
 	mu       sync.RWMutex
 	running  bool
 	shutdown chan struct{}
 }
 
-func NewControlPlane(cfg *config.Config) (*ControlPlane, error) {
-	// Initialize database
-	pgDB, err := db.NewPostgresDB(&cfg.Database)
+// NewControlPlane connects to Postgres, Redis, and NATS. It blocks,
+// retrying with backoff, until Postgres is reachable or ctx is done;
+// callers that want an overall startup deadline should pass a ctx with
+// a timeout.
+func NewControlPlane(ctx context.Context, cfg *config.Config) (*ControlPlane, error) {
+	// Initialize database, waiting for it to become reachable rather
+	// than failing fast
+	pgDB, err := db.NewPostgresDB(ctx, &cfg.Database)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize postgres: %w", err)
 	}
@@ -64,9 +72,11 @@ func NewControlPlane(cfg *config.Config) (*ControlPlane, error) {
 		shutdown: make(chan struct{}),
 	}
 
-	// Initialize scheduler and monitor
+	// Initialize scheduler, monitor, and schedule runner
 	cp.scheduler = NewScheduler(cp)
 	cp.monitor = NewMonitor(cp)
+	cp.scheduleRunner = NewScheduleRunner(cp)
+	cp.resultReducer = NewResultReducer(cp)
 
 	return cp, nil
 }
@@ -79,10 +89,9 @@ func (cp *ControlPlane) Start(ctx context.Context) error {
 		return fmt.Errorf("control plane already running")
 	}
 
-	// Run database migrations
-	if err := cp.db.RunMigrations("./migrations"); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
-	}
+	// Migrations are no longer run here; deploys run `agentflow migrate up`
+	// ahead of starting the control plane so schema changes don't race
+	// with replicas starting concurrently.
 
 	// Initialize NATS streams
 	if err := cp.initStreams(); err != nil {
@@ -99,6 +108,16 @@ func (cp *ControlPlane) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to start monitor: %w", err)
 	}
 
+	// Start schedule runner
+	if err := cp.scheduleRunner.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start schedule runner: %w", err)
+	}
+
+	// Start result reducer, the single writer of workflow_run aggregates
+	if err := cp.resultReducer.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start result reducer: %w", err)
+	}
+
 	cp.running = true
 	log.Println("Control plane started")
 
@@ -122,10 +141,19 @@ func (cp *ControlPlane) Shutdown(ctx context.Context) error {
 	if cp.monitor != nil {
 		cp.monitor.Shutdown(ctx)
 	}
+	if cp.scheduleRunner != nil {
+		cp.scheduleRunner.Shutdown(ctx)
+	}
+	if cp.resultReducer != nil {
+		cp.resultReducer.Shutdown(ctx)
+	}
 
-	// Close connections
+	// Close connections. Drain rather than abruptly close so any
+	// in-flight event publishes complete first.
 	if cp.nats != nil {
-		cp.nats.Close()
+		if err := cp.nats.Drain(); err != nil {
+			log.Printf("Failed to drain NATS connection: %v", err)
+		}
 	}
 	if cp.redis != nil {
 		cp.redis.Close()
@@ -165,6 +193,10 @@ func (cp *ControlPlane) SubmitWorkflow(ctx context.Context, req *RunRequest) (*W
 		return nil, fmt.Errorf("failed to save workflow run: %w", err)
 	}
 
+	if err := cp.publishEvent(ctx, EventTypeRunCreated, "workflow_run", run.ID, run.ID, run); err != nil {
+		log.Printf("Failed to publish run.created event: %v", err)
+	}
+
 	// Submit to scheduler
 	if err := cp.scheduler.SubmitRun(ctx, run, spec); err != nil {
 		return nil, fmt.Errorf("failed to submit run to scheduler: %w", err)
@@ -224,30 +256,28 @@ func (cp *ControlPlane) CancelWorkflowRun(ctx context.Context, runID uuid.UUID)
 		log.Printf("Failed to send cancellation signal: %v", err)
 	}
 
+	if err := cp.publishEvent(ctx, EventTypeRunCanceled, "workflow_run", runID, runID, cancelMsg); err != nil {
+		log.Printf("Failed to publish run.canceled event: %v", err)
+	}
+
 	return nil
 }
 
 func (cp *ControlPlane) initStreams() error {
-	streams := []struct {
-		name     string
-		subjects []string
-	}{
-		{"AGENTFLOW_TASKS", []string{"agentflow.tasks.*"}},
-		{"AGENTFLOW_RESULTS", []string{"agentflow.results.*"}},
-		{"AGENTFLOW_SIGNALS", []string{"agentflow.signals"}},
-	}
-
-	for _, stream := range streams {
-		_, err := cp.js.AddStream(&nats.StreamConfig{
-			Name:     stream.name,
-			Subjects: stream.subjects,
-			MaxAge:   24 * time.Hour,
-		})
+	for _, streamCfg := range bus.StreamConfigs() {
+		cfg := *streamCfg
+		cfg.MaxAge = 24 * time.Hour
+
+		_, err := cp.js.AddStream(&cfg)
 		if err != nil && err != nats.ErrStreamNameAlreadyInUse {
-			return fmt.Errorf("failed to create stream %s: %w", stream.name, err)
+			return fmt.Errorf("failed to create stream %s: %w", cfg.Name, err)
 		}
 	}
 
+	if _, err := cp.js.AddConsumer(bus.StreamResults, bus.ResultConsumerConfig()); err != nil && err != nats.ErrConsumerNameAlreadyInUse {
+		return fmt.Errorf("failed to create result-reducer consumer: %w", err)
+	}
+
 	return nil
 }
 
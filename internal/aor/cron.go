@@ -0,0 +1,115 @@
+package aor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed standard five-field cron expression:
+// minute hour day-of-month month day-of-week.
+type cronSchedule struct {
+	minutes    map[int]bool
+	hours      map[int]bool
+	daysOfMon  map[int]bool
+	months     map[int]bool
+	daysOfWeek map[int]bool
+}
+
+// parseCron parses a standard five-field cron expression. It supports
+// "*", single values, comma-separated lists, and "*/n" step syntax.
+func parseCron(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields, got %d", expr, len(fields))
+	}
+
+	minutes, err := parseCronField(fields[0], 0, 59)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minute field: %w", err)
+	}
+	hours, err := parseCronField(fields[1], 0, 23)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hour field: %w", err)
+	}
+	daysOfMon, err := parseCronField(fields[2], 1, 31)
+	if err != nil {
+		return nil, fmt.Errorf("invalid day-of-month field: %w", err)
+	}
+	months, err := parseCronField(fields[3], 1, 12)
+	if err != nil {
+		return nil, fmt.Errorf("invalid month field: %w", err)
+	}
+	daysOfWeek, err := parseCronField(fields[4], 0, 6)
+	if err != nil {
+		return nil, fmt.Errorf("invalid day-of-week field: %w", err)
+	}
+
+	return &cronSchedule{
+		minutes:    minutes,
+		hours:      hours,
+		daysOfMon:  daysOfMon,
+		months:     months,
+		daysOfWeek: daysOfWeek,
+	}, nil
+}
+
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	values := make(map[int]bool)
+
+	for _, part := range strings.Split(field, ",") {
+		if part == "*" {
+			for v := min; v <= max; v++ {
+				values[v] = true
+			}
+			continue
+		}
+
+		if strings.HasPrefix(part, "*/") {
+			step, err := strconv.Atoi(strings.TrimPrefix(part, "*/"))
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", part)
+			}
+			for v := min; v <= max; v += step {
+				values[v] = true
+			}
+			continue
+		}
+
+		v, err := strconv.Atoi(part)
+		if err != nil || v < min || v > max {
+			return nil, fmt.Errorf("invalid value %q", part)
+		}
+		values[v] = true
+	}
+
+	return values, nil
+}
+
+// Matches reports whether t falls on a minute this schedule should fire.
+// Matching a cron's day fields follows the usual convention: if both
+// day-of-month and day-of-week are restricted (not "*"), either matching
+// is sufficient.
+func (s *cronSchedule) Matches(t time.Time) bool {
+	if !s.minutes[t.Minute()] || !s.hours[t.Hour()] || !s.months[int(t.Month())] {
+		return false
+	}
+
+	domRestricted := len(s.daysOfMon) < 31
+	dowRestricted := len(s.daysOfWeek) < 7
+
+	domMatch := s.daysOfMon[t.Day()]
+	dowMatch := s.daysOfWeek[int(t.Weekday())]
+
+	switch {
+	case domRestricted && dowRestricted:
+		return domMatch || dowMatch
+	case domRestricted:
+		return domMatch
+	case dowRestricted:
+		return dowMatch
+	default:
+		return true
+	}
+}
@@ -0,0 +1,87 @@
+package aor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType identifies the kind of state change an Event represents.
+type EventType string
+
+const (
+	EventTypeRunCreated  EventType = "run.created"
+	EventTypeRunUpdated  EventType = "run.updated"
+	EventTypeRunCanceled EventType = "run.canceled"
+	EventTypeStepUpdated EventType = "step.updated"
+	EventTypeSignal      EventType = "signal"
+)
+
+// Event is a single, replayable state-change notification for a
+// WorkflowRun, StepRun, or signal. Events are published to NATS as they
+// happen and mirrored into the workflow_event table so that subscribers
+// who connect after the fact can backfill from that table, including
+// events older than the stream's retention window.
+type Event struct {
+	ID         uuid.UUID       `json:"id"`
+	Type       EventType       `json:"type"`
+	ObjectType string          `json:"object_type"`
+	ObjectID   uuid.UUID       `json:"object_id"`
+	RunID      uuid.UUID       `json:"run_id"`
+	Payload    json.RawMessage `json:"payload"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// eventSubject returns the NATS subject an event of this type is
+// published to, e.g. agentflow.events.run.created.
+func eventSubject(evtType EventType) string {
+	switch {
+	case evtType == EventTypeSignal:
+		return "agentflow.events.signal"
+	default:
+		return fmt.Sprintf("agentflow.events.%s", evtType)
+	}
+}
+
+// publishEvent persists evt to the workflow_event table and publishes it
+// to NATS so that live SSE/WebSocket subscribers and ResultReducer-style
+// consumers observe it immediately, while late subscribers can still
+// replay it from the table.
+func (cp *ControlPlane) publishEvent(ctx context.Context, evtType EventType, objectType string, objectID, runID uuid.UUID, payload interface{}) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event payload: %w", err)
+	}
+
+	evt := &Event{
+		ID:         uuid.New(),
+		Type:       evtType,
+		ObjectType: objectType,
+		ObjectID:   objectID,
+		RunID:      runID,
+		Payload:    payloadJSON,
+		CreatedAt:  time.Now(),
+	}
+
+	query := `INSERT INTO workflow_event (id, type, object_type, object_id, run_id, payload, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	if _, err := cp.db.ExecContext(ctx, query,
+		evt.ID, evt.Type, evt.ObjectType, evt.ObjectID, evt.RunID, evt.Payload, evt.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to persist event: %w", err)
+	}
+
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	if _, err := cp.js.Publish(eventSubject(evtType), data); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	return nil
+}
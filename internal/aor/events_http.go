@@ -0,0 +1,179 @@
+package aor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+)
+
+// StreamRunEvents handles GET /api/v1/runs/:id/events, streaming every
+// Event for a single WorkflowRun as Server-Sent Events until the client
+// disconnects.
+func (cp *ControlPlane) StreamRunEvents(c *gin.Context) {
+	runID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid run id"})
+		return
+	}
+
+	// Open the live subscription before running the backfill query so an
+	// event published while the backfill is still in flight lands in the
+	// live stream instead of the gap between the two.
+	sub, err := cp.openEventSubscription(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to open event consumer: %v", err)})
+		return
+	}
+	defer sub.Unsubscribe()
+
+	if c.Query("past") == "true" {
+		past, err := cp.backfillRunEvents(c, runID)
+		if err != nil {
+			log.Printf("Failed to backfill run events: %v", err)
+		}
+		for _, evt := range past {
+			data, err := json.Marshal(evt)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", evt.Type, data)
+		}
+		c.Writer.Flush()
+	}
+
+	cp.runEventLoop(c, sub, func(evt *Event) bool {
+		return evt.RunID == runID
+	})
+}
+
+// StreamEvents handles GET /api/v1/events?types=run,step,signal, streaming
+// Events across all runs, optionally filtered by event category.
+//
+// ?workflow= is part of the client SDK's StreamOptions but isn't
+// supported yet: Event carries run/object IDs, not a workflow spec name,
+// so there's nothing to filter on. Reject it with 400 rather than
+// silently streaming everything, since a caller setting it has no other
+// way to find out the filter didn't apply.
+func (cp *ControlPlane) StreamEvents(c *gin.Context) {
+	if c.Query("workflow") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workflow filter is not supported yet"})
+		return
+	}
+
+	var types map[string]bool
+	if raw := c.Query("types"); raw != "" {
+		types = make(map[string]bool)
+		for _, t := range strings.Split(raw, ",") {
+			types[strings.TrimSpace(t)] = true
+		}
+	}
+
+	sub, err := cp.openEventSubscription(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to open event consumer: %v", err)})
+		return
+	}
+	defer sub.Unsubscribe()
+
+	cp.runEventLoop(c, sub, func(evt *Event) bool {
+		if types != nil && !types[eventCategory(evt.Type)] {
+			return false
+		}
+		return true
+	})
+}
+
+// eventCategory maps an Event's concrete Type (e.g. "run.created",
+// "step.updated") to the coarse category used by the ?types= filter
+// ("run", "step", "signal").
+func eventCategory(t EventType) string {
+	switch {
+	case strings.HasPrefix(string(t), "run."):
+		return "run"
+	case strings.HasPrefix(string(t), "step."):
+		return "step"
+	case t == EventTypeSignal:
+		return "signal"
+	default:
+		return ""
+	}
+}
+
+// openEventSubscription sets the SSE response headers and opens a
+// JetStream ephemeral consumer anchored at "now" on the events stream.
+// Callers open this before running any backfill query so the live stream
+// already covers everything published from this point on, leaving no gap
+// between a backfill snapshot and the subscription picking up.
+func (cp *ControlPlane) openEventSubscription(c *gin.Context) (*nats.Subscription, error) {
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	return cp.js.SubscribeSync("agentflow.events.>", nats.DeliverNew(), nats.AckNone())
+}
+
+// runEventLoop writes every event accepted by keep as an SSE frame until
+// the request context is canceled.
+func (cp *ControlPlane) runEventLoop(c *gin.Context, sub *nats.Subscription, keep func(*Event) bool) {
+	w := c.Writer
+	ctx := c.Request.Context()
+	for {
+		msg, err := sub.NextMsgWithContext(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Failed to read next event: %v", err)
+			return
+		}
+
+		var evt Event
+		if err := json.Unmarshal(msg.Data, &evt); err != nil {
+			log.Printf("Failed to unmarshal event: %v", err)
+			continue
+		}
+
+		if !keep(&evt) {
+			continue
+		}
+
+		fmt.Fprintf(w, "event: %s\n", evt.Type)
+		fmt.Fprintf(w, "data: %s\n\n", msg.Data)
+		w.Flush()
+	}
+}
+
+// backfillRunEvents loads events for a run from the workflow_event table,
+// used by StreamRunEvents when a subscriber wants a replay beyond the
+// JetStream retention window.
+func (cp *ControlPlane) backfillRunEvents(c *gin.Context, runID uuid.UUID) ([]*Event, error) {
+	query := `SELECT id, type, object_type, object_id, run_id, payload, created_at
+			  FROM workflow_event WHERE run_id = $1 ORDER BY created_at ASC`
+
+	rows, err := cp.db.QueryContext(c.Request.Context(), query, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workflow events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		var evt Event
+		var createdAt time.Time
+		if err := rows.Scan(&evt.ID, &evt.Type, &evt.ObjectType, &evt.ObjectID, &evt.RunID, &evt.Payload, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow event: %w", err)
+		}
+		evt.CreatedAt = createdAt
+		events = append(events, &evt)
+	}
+
+	return events, rows.Err()
+}
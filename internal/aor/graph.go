@@ -0,0 +1,152 @@
+package aor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GraphNode is a single node in a run's job graph: a DAG node merged with
+// the current status of the StepRun executing it, if one exists yet.
+type GraphNode struct {
+	ID        string     `json:"id"`
+	Type      NodeType   `json:"type"`
+	Status    StepStatus `json:"status"`
+	StepID    *uuid.UUID `json:"step_id,omitempty"`
+	StartedAt *string    `json:"started_at,omitempty"`
+	EndedAt   *string    `json:"ended_at,omitempty"`
+	CostCents int64      `json:"cost_cents"`
+}
+
+// GraphEdge is a dependency edge between two DAG node IDs.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RunGraph is the response for GET /api/v1/runs/:id/graph: a job graph
+// the dashboard renders as the run-inspection DAG view.
+type RunGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GetRunGraph handles GET /api/v1/runs/:id/graph, deriving the job graph
+// for a run from its WorkflowSpec.DAG joined with the current StepRun
+// statuses so the dashboard can render progress without re-walking the
+// spec itself.
+func (cp *ControlPlane) GetRunGraph(c *gin.Context) {
+	runID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid run id"})
+		return
+	}
+
+	run, err := cp.GetWorkflowRun(c.Request.Context(), runID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "run not found"})
+		return
+	}
+
+	spec, err := cp.getWorkflowSpecByID(c.Request.Context(), run.WorkflowSpecID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to load workflow spec: %v", err)})
+		return
+	}
+
+	steps, err := cp.listStepRuns(c.Request.Context(), runID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to load step runs: %v", err)})
+		return
+	}
+
+	stepByNodeID := make(map[string]*StepRun, len(steps))
+	for _, step := range steps {
+		stepByNodeID[step.NodeID] = step
+	}
+
+	graph := RunGraph{}
+	for _, node := range spec.DAG.Nodes {
+		gn := GraphNode{ID: node.ID, Type: node.Type, Status: StepStatusPending}
+		if step, ok := stepByNodeID[node.ID]; ok {
+			gn.Status = step.Status
+			gn.StepID = &step.ID
+			gn.CostCents = step.CostCents
+			if step.StartedAt != nil {
+				s := step.StartedAt.Format(httpTimeFormat)
+				gn.StartedAt = &s
+			}
+			if step.EndedAt != nil {
+				e := step.EndedAt.Format(httpTimeFormat)
+				gn.EndedAt = &e
+			}
+		}
+		graph.Nodes = append(graph.Nodes, gn)
+
+		for _, dep := range node.DependsOn {
+			graph.Edges = append(graph.Edges, GraphEdge{From: dep, To: node.ID})
+		}
+	}
+
+	c.JSON(http.StatusOK, graph)
+}
+
+const httpTimeFormat = "2006-01-02T15:04:05Z07:00"
+
+// getWorkflowSpecByID loads a WorkflowSpec by its primary key, used by
+// handlers that only have the run (and therefore the spec ID) on hand.
+func (cp *ControlPlane) getWorkflowSpecByID(ctx context.Context, specID uuid.UUID) (*WorkflowSpec, error) {
+	query := `SELECT id, org_id, name, version, dag, metadata FROM workflow_spec WHERE id = $1`
+
+	var spec WorkflowSpec
+	var dagJSON, metadataJSON []byte
+
+	err := cp.db.QueryRowContext(ctx, query, specID).Scan(
+		&spec.ID, &spec.OrgID, &spec.Name, &spec.Version, &dagJSON, &metadataJSON,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow spec: %w", err)
+	}
+
+	if err := json.Unmarshal(dagJSON, &spec.DAG); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal DAG: %w", err)
+	}
+	if err := json.Unmarshal(metadataJSON, &spec.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	return &spec, nil
+}
+
+// listStepRuns loads every StepRun belonging to a run, used to join
+// current execution status onto the static DAG for the graph view.
+func (cp *ControlPlane) listStepRuns(ctx context.Context, runID uuid.UUID) ([]*StepRun, error) {
+	query := `SELECT id, run_id, node_id, status, worker_id, started_at, ended_at, error, cost_cents,
+			  tokens_prompt, tokens_completion
+			  FROM step_run WHERE run_id = $1`
+
+	rows, err := cp.db.QueryContext(ctx, query, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query step runs: %w", err)
+	}
+	defer rows.Close()
+
+	var steps []*StepRun
+	for rows.Next() {
+		var step StepRun
+		if err := rows.Scan(
+			&step.ID, &step.RunID, &step.NodeID, &step.Status, &step.WorkerID,
+			&step.StartedAt, &step.EndedAt, &step.Error, &step.CostCents,
+			&step.TokensPrompt, &step.TokensCompletion,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan step run: %w", err)
+		}
+		steps = append(steps, &step)
+	}
+
+	return steps, rows.Err()
+}
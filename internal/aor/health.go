@@ -0,0 +1,34 @@
+package aor
+
+import "context"
+
+// HealthStatus is the /health response's overall status: starting while
+// Postgres hasn't come up yet, degraded once Postgres is up but NATS or
+// Redis isn't, and ok once every dependency is reachable.
+type HealthStatus string
+
+const (
+	HealthStarting HealthStatus = "starting"
+	HealthDegraded HealthStatus = "degraded"
+	HealthOK       HealthStatus = "ok"
+)
+
+// Health reports the reachability of every dependency the control plane
+// holds a connection to, so cmd/server's /health handler can distinguish
+// "still waiting on the database" from "up but a dependency is down".
+func (cp *ControlPlane) Health(ctx context.Context) (status HealthStatus, dbOK, queueOK, redisOK bool) {
+	dbOK = cp.db != nil && cp.db.PingContext(ctx) == nil
+	queueOK = cp.nats != nil && cp.nats.IsConnected()
+	redisOK = cp.redis != nil && cp.redis.Ping(ctx).Err() == nil
+
+	switch {
+	case !dbOK:
+		status = HealthStarting
+	case !queueOK || !redisOK:
+		status = HealthDegraded
+	default:
+		status = HealthOK
+	}
+
+	return status, dbOK, queueOK, redisOK
+}
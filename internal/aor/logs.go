@@ -0,0 +1,201 @@
+package aor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// LogSink is the destination executors write structured log lines and
+// stdout to while a step runs. Worker.handleTask tees each executor's
+// output into one of these so the run viewer can stream it live and
+// replay it after the step finishes.
+type LogSink interface {
+	WriteLine(ctx context.Context, stepID uuid.UUID, line string) error
+}
+
+// dbStepLogSink persists log lines straight through the worker's
+// *db.PostgresDB, matching how every other worker write path works.
+type dbStepLogSink struct {
+	w *Worker
+}
+
+// NewStepLogSink returns the LogSink a Worker tees executor output into.
+func NewStepLogSink(w *Worker) LogSink {
+	return &dbStepLogSink{w: w}
+}
+
+func (s *dbStepLogSink) WriteLine(ctx context.Context, stepID uuid.UUID, line string) error {
+	query := `INSERT INTO step_log (id, step_id, line, created_at) VALUES ($1, $2, $3, $4)`
+	_, err := s.w.db.ExecContext(ctx, query, uuid.New(), stepID, line, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to persist step log line: %w", err)
+	}
+	return nil
+}
+
+// GetStepLogs handles GET /api/v1/runs/:id/steps/:step_id/logs?follow=true,
+// writing previously persisted lines from step_log and, when follow=true,
+// continuing to poll for new ones until the step finishes or the client
+// disconnects.
+func (cp *ControlPlane) GetStepLogs(c *gin.Context) {
+	stepID, err := uuid.Parse(c.Param("step_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid step id"})
+		return
+	}
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("Transfer-Encoding", "chunked")
+
+	w := bufio.NewWriter(c.Writer)
+	defer w.Flush()
+
+	var lastCreatedAt time.Time
+	for {
+		// Read the status before fetching, so that once a finished status
+		// is seen this fetch still returns every line written before it.
+		status, statusErr := cp.getStepStatus(c.Request.Context(), stepID)
+		finished := statusErr == nil && (status == StepStatusCompleted || status == StepStatusFailed || status == StepStatusCanceled)
+
+		lines, next, err := cp.fetchStepLogLines(c.Request.Context(), stepID, lastCreatedAt)
+		if err != nil {
+			log.Printf("Failed to fetch step log lines: %v", err)
+			return
+		}
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+		}
+		w.Flush()
+		c.Writer.Flush()
+
+		if !next.IsZero() {
+			lastCreatedAt = next
+		}
+
+		if c.Query("follow") != "true" || finished {
+			return
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func (cp *ControlPlane) fetchStepLogLines(ctx context.Context, stepID uuid.UUID, after time.Time) ([]string, time.Time, error) {
+	query := `SELECT line, created_at FROM step_log WHERE step_id = $1 AND created_at > $2 ORDER BY created_at ASC`
+
+	rows, err := cp.db.QueryContext(ctx, query, stepID, after)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to query step logs: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []string
+	var last time.Time
+	for rows.Next() {
+		var line string
+		var createdAt time.Time
+		if err := rows.Scan(&line, &createdAt); err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to scan step log line: %w", err)
+		}
+		lines = append(lines, line)
+		last = createdAt
+	}
+
+	return lines, last, rows.Err()
+}
+
+func (cp *ControlPlane) getStepStatus(ctx context.Context, stepID uuid.UUID) (StepStatus, error) {
+	var status StepStatus
+	err := cp.db.QueryRowContext(ctx, `SELECT status FROM step_run WHERE id = $1`, stepID).Scan(&status)
+	return status, err
+}
+
+// RerunStep handles POST /api/v1/runs/:id/steps/:step_id/rerun. It resets
+// the StepRun back to queued, clearing its error and start/end times, and
+// re-enqueues its task so a worker picks it up again. Earlier attempts'
+// lines in step_log are left in place for comparison in the viewer.
+func (cp *ControlPlane) RerunStep(c *gin.Context) {
+	runID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid run id"})
+		return
+	}
+
+	stepID, err := uuid.Parse(c.Param("step_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid step id"})
+		return
+	}
+
+	query := `UPDATE step_run SET status = $1, started_at = NULL, ended_at = NULL, error = NULL
+			  WHERE id = $2 AND run_id = $3 AND status IN ('failed', 'completed', 'canceled')`
+	result, err := cp.db.ExecContext(c.Request.Context(), query, StepStatusQueued, stepID, runID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to reset step: %v", err)})
+		return
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "step not found or not in a rerunnable state"})
+		return
+	}
+
+	if err := cp.scheduler.EnqueueStep(c.Request.Context(), stepID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to re-enqueue step: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"step_id": stepID, "status": StepStatusQueued})
+}
+
+// ApproveRun handles POST /api/v1/runs/:id/approve, unblocking a run that
+// is paused on a human-gated node. It records the approval on the
+// pending StepRun and asks the scheduler to resume dispatch.
+func (cp *ControlPlane) ApproveRun(c *gin.Context) {
+	runID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid run id"})
+		return
+	}
+
+	var req struct {
+		StepID  uuid.UUID `json:"step_id" binding:"required"`
+		Comment string    `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := `UPDATE step_run SET status = $1 WHERE id = $2 AND run_id = $3 AND status = 'awaiting_approval'`
+	result, err := cp.db.ExecContext(c.Request.Context(), query, StepStatusQueued, req.StepID, runID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to approve step: %v", err)})
+		return
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "step not awaiting approval"})
+		return
+	}
+
+	if err := cp.scheduler.EnqueueStep(c.Request.Context(), req.StepID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to resume run: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"run_id": runID, "step_id": req.StepID, "status": "approved"})
+}
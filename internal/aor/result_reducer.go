@@ -0,0 +1,105 @@
+package aor
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/aor/bus"
+	"github.com/nats-io/nats.go"
+)
+
+// ResultReducer is the single writer of workflow_run aggregates (cost
+// rollups, step completion counts). Centralizing these writes here, off
+// of a durable pull consumer on AGENTFLOW_RESULTS, removes the race that
+// used to exist between Worker.updateStepWithResult and scheduler
+// decisions reading the same row concurrently.
+type ResultReducer struct {
+	cp *ControlPlane
+
+	shutdown chan struct{}
+	done     chan struct{}
+}
+
+// NewResultReducer returns a ResultReducer bound to cp.
+func NewResultReducer(cp *ControlPlane) *ResultReducer {
+	return &ResultReducer{
+		cp:       cp,
+		shutdown: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start begins pulling batches of results and reducing them into
+// workflow_run aggregates until ctx is canceled or Shutdown is called.
+func (rr *ResultReducer) Start(ctx context.Context) error {
+	consumerCfg := bus.ResultConsumerConfig()
+	sub, err := rr.cp.js.PullSubscribe("agentflow.results.*", consumerCfg.Durable, nats.Bind(bus.StreamResults, consumerCfg.Durable))
+	if err != nil {
+		return err
+	}
+
+	go rr.run(ctx, sub)
+	return nil
+}
+
+// Shutdown stops the reduce loop and waits for the in-flight batch to
+// finish.
+func (rr *ResultReducer) Shutdown(ctx context.Context) error {
+	close(rr.shutdown)
+	select {
+	case <-rr.done:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func (rr *ResultReducer) run(ctx context.Context, sub *nats.Subscription) {
+	defer close(rr.done)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-rr.shutdown:
+			return
+		default:
+		}
+
+		msgs, err := sub.Fetch(32, nats.MaxWait(1*time.Second))
+		if err != nil {
+			if err != nats.ErrTimeout {
+				log.Printf("Failed to fetch results: %v", err)
+			}
+			continue
+		}
+
+		for _, msg := range msgs {
+			if err := rr.reduce(ctx, msg); err != nil {
+				log.Printf("Failed to reduce result: %v", err)
+				msg.Nak()
+				continue
+			}
+			msg.Ack()
+		}
+	}
+}
+
+func (rr *ResultReducer) reduce(ctx context.Context, msg *nats.Msg) error {
+	var result TaskResult
+	if err := json.Unmarshal(msg.Data, &result); err != nil {
+		return err
+	}
+
+	query := `UPDATE workflow_run
+			  SET cost_cents = cost_cents + $1,
+				  metadata = jsonb_set(
+					  jsonb_set(metadata, '{tokens_prompt}', to_jsonb(coalesce((metadata->>'tokens_prompt')::bigint, 0) + $2::bigint)),
+					  '{steps_completed}', to_jsonb(coalesce((metadata->>'steps_completed')::bigint, 0) + 1)
+				  )
+			  WHERE id = (SELECT run_id FROM step_run WHERE id = $3)`
+
+	_, err := rr.cp.db.ExecContext(ctx, query, result.CostCents, result.TokensPrompt, result.TaskID)
+	return err
+}
@@ -0,0 +1,244 @@
+package aor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CatchupPolicy is a per-schedule setting for fire times missed while the
+// control plane was down. It is stored with each ScheduleSpec, but
+// ScheduleRunner does not read it: whatever the policy, a tick fires only
+// schedules whose cron matches the current minute, and missed times get a
+// run only through BackfillSchedule.
+type CatchupPolicy string
+
+const (
+	CatchupPolicySkip CatchupPolicy = "skip"
+	CatchupPolicyOne  CatchupPolicy = "one"
+	CatchupPolicyAll  CatchupPolicy = "all"
+)
+
+// ScheduleSpec defines a recurring or interval trigger for a workflow,
+// analogous to a WorkflowSpec but for the inputs that govern when a run
+// is submitted rather than how it executes.
+type ScheduleSpec struct {
+	ID              uuid.UUID     `json:"id"`
+	WorkflowName    string        `json:"workflow_name"`
+	WorkflowVersion int           `json:"workflow_version"`
+	Cron            string        `json:"cron"`
+	Timezone        string        `json:"timezone"`
+	Inputs          Metadata      `json:"inputs"`
+	BudgetCents     int64         `json:"budget_cents"`
+	Paused          bool          `json:"paused"`
+	CatchupPolicy   CatchupPolicy `json:"catchup_policy"`
+	MaxConcurrent   int           `json:"max_concurrent"`
+	CreatedAt       time.Time     `json:"created_at"`
+}
+
+// ScheduleRunner polls due ScheduleSpecs and submits workflow runs for
+// them, holding a Redis leader lock so that only one control-plane
+// replica fires a given schedule.
+type ScheduleRunner struct {
+	cp *ControlPlane
+
+	shutdown chan struct{}
+	done     chan struct{}
+}
+
+// NewScheduleRunner returns a ScheduleRunner bound to cp, mirroring how
+// Scheduler and Monitor are constructed.
+func NewScheduleRunner(cp *ControlPlane) *ScheduleRunner {
+	return &ScheduleRunner{
+		cp:       cp,
+		shutdown: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start begins polling for due schedules every tick until ctx is
+// canceled or Shutdown is called.
+func (sr *ScheduleRunner) Start(ctx context.Context) error {
+	go sr.run(ctx)
+	return nil
+}
+
+// Shutdown stops the polling loop and waits for the in-flight tick to
+// finish.
+func (sr *ScheduleRunner) Shutdown(ctx context.Context) error {
+	close(sr.shutdown)
+	select {
+	case <-sr.done:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func (sr *ScheduleRunner) run(ctx context.Context) {
+	defer close(sr.done)
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sr.shutdown:
+			return
+		case now := <-ticker.C:
+			sr.tick(ctx, now)
+		}
+	}
+}
+
+func (sr *ScheduleRunner) tick(ctx context.Context, now time.Time) {
+	schedules, err := sr.cp.listDueSchedules(ctx, now)
+	if err != nil {
+		log.Printf("Failed to list due schedules: %v", err)
+		return
+	}
+
+	for _, sched := range schedules {
+		if err := sr.fire(ctx, sched, now); err != nil {
+			log.Printf("Failed to fire schedule %s: %v", sched.ID, err)
+		}
+	}
+}
+
+// fire claims the leader lock for sched, records the firing with a
+// dedupe key so a restart can't double-submit it, checks MaxConcurrent,
+// and submits the run.
+func (sr *ScheduleRunner) fire(ctx context.Context, sched *ScheduleSpec, fireTime time.Time) error {
+	lockKey := fmt.Sprintf("agentflow:schedule-lock:%s", sched.ID)
+	ok, err := sr.cp.redis.SetNX(ctx, lockKey, sr.cp.cfg.InstanceID, 30*time.Second).Result()
+	if err != nil {
+		return fmt.Errorf("failed to acquire schedule leader lock: %w", err)
+	}
+	if !ok {
+		// Another replica already owns this tick.
+		return nil
+	}
+	defer sr.cp.redis.Del(ctx, lockKey)
+
+	if sched.MaxConcurrent > 0 {
+		active, err := sr.cp.countActiveRunsForSchedule(ctx, sched.ID)
+		if err != nil {
+			return fmt.Errorf("failed to count active runs: %w", err)
+		}
+		if active >= sched.MaxConcurrent {
+			log.Printf("Schedule %s skipped: %d/%d runs already active", sched.ID, active, sched.MaxConcurrent)
+			return nil
+		}
+	}
+
+	// Claim the dedupe key only once the fire is actually going to submit
+	// a run, so a fire skipped for being over MaxConcurrent stays
+	// unclaimed and can still be caught up by a later tick or backfill.
+	dedupeKey := fireTime.Truncate(time.Minute)
+	claimed, err := sr.cp.claimScheduleFire(ctx, sched.ID, dedupeKey)
+	if err != nil {
+		return fmt.Errorf("failed to claim schedule fire: %w", err)
+	}
+	if !claimed {
+		// Already fired for this minute, e.g. after a restart.
+		return nil
+	}
+
+	_, err = sr.cp.SubmitWorkflow(ctx, &RunRequest{
+		WorkflowName:    sched.WorkflowName,
+		WorkflowVersion: sched.WorkflowVersion,
+		Inputs:          sched.Inputs,
+		BudgetCents:     sched.BudgetCents,
+		Tags:            []string{fmt.Sprintf("schedule:%s", sched.ID)},
+	})
+	return err
+}
+
+func (cp *ControlPlane) listDueSchedules(ctx context.Context, now time.Time) ([]*ScheduleSpec, error) {
+	query := `SELECT id, workflow_name, workflow_version, cron, timezone, inputs, budget_cents,
+			  paused, catchup_policy, max_concurrent, created_at
+			  FROM schedule_spec WHERE paused = false`
+
+	rows, err := cp.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var due []*ScheduleSpec
+	for rows.Next() {
+		var sched ScheduleSpec
+		var inputsJSON []byte
+		if err := rows.Scan(
+			&sched.ID, &sched.WorkflowName, &sched.WorkflowVersion, &sched.Cron, &sched.Timezone,
+			&inputsJSON, &sched.BudgetCents, &sched.Paused, &sched.CatchupPolicy, &sched.MaxConcurrent,
+			&sched.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		if err := json.Unmarshal(inputsJSON, &sched.Inputs); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal schedule inputs: %w", err)
+		}
+		if cronDue(sched.Cron, sched.Timezone, now) {
+			due = append(due, &sched)
+		}
+	}
+
+	return due, rows.Err()
+}
+
+// claimScheduleFire inserts a dedupe row for (schedule_id, fire_time) and
+// reports whether this call won the race, so a replica that crashes
+// mid-submit can't double-fire on restart.
+func (cp *ControlPlane) claimScheduleFire(ctx context.Context, scheduleID uuid.UUID, fireTime time.Time) (bool, error) {
+	query := `INSERT INTO schedule_fire (id, schedule_id, fire_time, created_at)
+			  VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (schedule_id, fire_time) DO NOTHING`
+
+	result, err := cp.db.ExecContext(ctx, query, uuid.New(), scheduleID, fireTime, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (cp *ControlPlane) countActiveRunsForSchedule(ctx context.Context, scheduleID uuid.UUID) (int, error) {
+	query := `SELECT count(*) FROM workflow_run
+			  WHERE status IN ('queued', 'running') AND metadata->'tags' @> $1`
+
+	tag, err := json.Marshal([]string{fmt.Sprintf("schedule:%s", scheduleID)})
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = cp.db.QueryRowContext(ctx, query, tag).Scan(&count)
+	return count, err
+}
+
+// cronDue reports whether cron expr is due to fire at now in the given
+// timezone. It is implemented in terms of a standard five-field cron
+// parser, evaluated once per ScheduleRunner tick.
+func cronDue(expr string, timezone string, now time.Time) bool {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	sched, err := parseCron(expr)
+	if err != nil {
+		log.Printf("Invalid cron expression %q: %v", expr, err)
+		return false
+	}
+	return sched.Matches(now.In(loc))
+}
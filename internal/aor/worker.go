@@ -1,20 +1,26 @@
 package aor
 
 import (
-	"github.com/google/uuid"
-	"context"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/aor/bus"
 	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/config"
 	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/db"
+	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
 	"github.com/redis/go-redis/v9"
 )
 
+// defaultWorkerConcurrency caps how many tasks, across every priority,
+// a single worker process executes at once when cfg.Worker.Concurrency
+// isn't set.
+const defaultWorkerConcurrency = 10
+
 type Worker struct {
 	id       string
 	cfg      *config.Config
@@ -22,21 +28,36 @@ type Worker struct {
 	redis    *redis.Client
 	nats     *nats.Conn
 	js       nats.JetStreamContext
-	
+
 	executors map[NodeType]Executor
-	
+
+	// goldSem and sharedSem together bound total concurrent task
+	// execution. goldSem holds slots only Gold's fetch loop may acquire,
+	// giving it guaranteed throughput under load; sharedSem holds the
+	// remaining slots and is contended for by all three priorities, with
+	// Gold additionally able to fall back to it once goldSem is full.
+	// Silver and Bronze can only ever acquire from sharedSem.
+	goldSem   chan struct{}
+	sharedSem chan struct{}
+
 	mu       sync.RWMutex
 	running  bool
+	draining bool
 	shutdown chan struct{}
+	inFlight sync.WaitGroup
 }
 
 type Executor interface {
-	Execute(ctx context.Context, task *Task) (*TaskResult, error)
+	Execute(ctx context.Context, task *Task, logs LogSink) (*TaskResult, error)
 }
 
-func NewWorker(cfg *config.Config) (*Worker, error) {
-	// Initialize database
-	pgDB, err := db.NewPostgresDB(&cfg.Database)
+// NewWorker connects to Postgres, Redis, and NATS. Like NewControlPlane,
+// it blocks retrying with backoff until Postgres is reachable or ctx is
+// done.
+func NewWorker(ctx context.Context, cfg *config.Config) (*Worker, error) {
+	// Initialize database, waiting for it to become reachable rather
+	// than failing fast
+	pgDB, err := db.NewPostgresDB(ctx, &cfg.Database)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize postgres: %w", err)
 	}
@@ -80,26 +101,39 @@ func NewWorker(cfg *config.Config) (*Worker, error) {
 
 func (w *Worker) Start(ctx context.Context) error {
 	w.mu.Lock()
-	defer w.mu.Unlock()
-
 	if w.running {
+		w.mu.Unlock()
 		return fmt.Errorf("worker already running")
 	}
 
-	w.running = true
-
-	// Subscribe to task queues
-	subjects := []string{
-		"agentflow.tasks.Gold",
-		"agentflow.tasks.Silver", 
-		"agentflow.tasks.Bronze",
+	concurrency := w.cfg.Worker.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultWorkerConcurrency
 	}
+	// Reserve half the worker's capacity for Gold alone; the rest is
+	// shared across all three tiers.
+	goldReserved := concurrency / 2
+	w.goldSem = make(chan struct{}, goldReserved)
+	w.sharedSem = make(chan struct{}, concurrency-goldReserved)
+	w.running = true
+	w.mu.Unlock()
+
+	// One durable pull consumer per priority, shared across every worker
+	// process rather than keyed by worker ID, so restarts don't leave
+	// orphaned durables behind and MaxAckPending throttles each tier
+	// independently.
+	for _, priority := range bus.Priorities {
+		consumerCfg := bus.TaskConsumerConfig(priority)
+		if _, err := w.js.AddConsumer(bus.StreamTasks, consumerCfg); err != nil && err != nats.ErrConsumerNameAlreadyInUse {
+			return fmt.Errorf("failed to create %s consumer: %w", priority, err)
+		}
 
-	for _, subject := range subjects {
-		_, err := w.js.Subscribe(subject, w.handleTask, nats.Durable(fmt.Sprintf("worker-%s", w.id)))
+		sub, err := w.js.PullSubscribe(bus.TaskSubject(priority), consumerCfg.Durable, nats.Bind(bus.StreamTasks, consumerCfg.Durable))
 		if err != nil {
-			return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+			return fmt.Errorf("failed to open pull subscription for %s: %w", priority, err)
 		}
+
+		go w.fetchLoop(ctx, priority, sub)
 	}
 
 	// Start heartbeat
@@ -109,20 +143,118 @@ func (w *Worker) Start(ctx context.Context) error {
 	return nil
 }
 
+// fetchLoop repeatedly pulls a single task for priority and dispatches it
+// onto a semaphore slot. Gold has its own reserved slots (goldSem) plus
+// access to the pool shared with Silver and Bronze, so it keeps
+// executing even when lower tiers have filled the shared pool.
+func (w *Worker) fetchLoop(ctx context.Context, priority bus.Priority, sub *nats.Subscription) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.shutdown:
+			return
+		default:
+		}
+
+		w.mu.RLock()
+		draining := w.draining
+		w.mu.RUnlock()
+		if draining {
+			return
+		}
+
+		msgs, err := sub.Fetch(1, nats.MaxWait(2*time.Second))
+		if err != nil {
+			if err != nats.ErrTimeout && err != context.DeadlineExceeded {
+				log.Printf("Failed to fetch %s task: %v", priority, err)
+			}
+			continue
+		}
+
+		for _, msg := range msgs {
+			release, ok := w.acquireSlot(ctx, priority)
+			if !ok {
+				return
+			}
+
+			w.inFlight.Add(1)
+			go func(m *nats.Msg) {
+				defer func() { release(); w.inFlight.Done() }()
+				w.handleTask(priority, m)
+			}(msg)
+		}
+	}
+}
+
+// acquireSlot blocks until a semaphore slot is available for priority, or
+// ctx is canceled. Gold tries its reserved pool first, falling back to
+// the shared pool so it doesn't sit idle while reserved slots are all in
+// use; Silver and Bronze only ever draw from the shared pool, so they can
+// never exhaust Gold's reservation.
+func (w *Worker) acquireSlot(ctx context.Context, priority bus.Priority) (release func(), ok bool) {
+	if priority == bus.PriorityGold {
+		select {
+		case w.goldSem <- struct{}{}:
+			return func() { <-w.goldSem }, true
+		default:
+		}
+
+		select {
+		case w.goldSem <- struct{}{}:
+			return func() { <-w.goldSem }, true
+		case w.sharedSem <- struct{}{}:
+			return func() { <-w.sharedSem }, true
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+
+	select {
+	case w.sharedSem <- struct{}{}:
+		return func() { <-w.sharedSem }, true
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
 func (w *Worker) Shutdown(ctx context.Context) error {
 	w.mu.Lock()
-	defer w.mu.Unlock()
-
 	if !w.running {
+		w.mu.Unlock()
 		return nil
 	}
 
+	// Stop accepting new tasks but let in-flight handleTask goroutines
+	// finish; new deliveries are Nak'd with a delay so another worker
+	// picks them up instead.
+	w.draining = true
 	close(w.shutdown)
+	w.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		w.inFlight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		log.Printf("Worker %s shutdown timed out waiting for in-flight tasks to finish", w.id)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	w.running = false
 
-	// Close connections
+	// Drain rather than abruptly close so in-flight NATS deliveries are
+	// acked/nak'd before the connection goes away.
 	if w.nats != nil {
-		w.nats.Close()
+		if err := w.nats.Drain(); err != nil {
+			log.Printf("Failed to drain NATS connection: %v", err)
+		}
 	}
 	if w.redis != nil {
 		w.redis.Close()
@@ -135,7 +267,21 @@ func (w *Worker) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-func (w *Worker) handleTask(msg *nats.Msg) {
+func (w *Worker) handleTask(priority bus.Priority, msg *nats.Msg) {
+	w.mu.RLock()
+	draining := w.draining
+	w.mu.RUnlock()
+
+	if draining {
+		msg.NakWithDelay(5 * time.Second)
+		return
+	}
+
+	if meta, err := msg.Metadata(); err == nil && meta.NumDelivered >= bus.TaskConsumerConfig(priority).MaxDeliver {
+		w.sendToDLQ(priority, msg)
+		return
+	}
+
 	var task Task
 	if err := json.Unmarshal(msg.Data, &task); err != nil {
 		log.Printf("Failed to unmarshal task: %v", err)
@@ -147,14 +293,18 @@ func (w *Worker) handleTask(msg *nats.Msg) {
 	defer cancel()
 
 	// Update step status to running
-	if err := w.updateStepStatus(ctx, task.ID, StepStatusRunning, w.id); err != nil {
+	if err := w.updateStepStatus(ctx, task.RunID, task.ID, StepStatusRunning, w.id); err != nil {
 		log.Printf("Failed to update step status to running: %v", err)
 		msg.Nak()
 		return
 	}
 
+	// Tee executor output to the step_log table so the run viewer can
+	// stream and replay it.
+	logs := NewStepLogSink(w)
+
 	// Execute task
-	result, err := w.executeTask(ctx, &task)
+	result, err := w.executeTask(ctx, &task, logs)
 	if err != nil {
 		log.Printf("Failed to execute task %s: %v", task.ID, err)
 		result = &TaskResult{
@@ -165,14 +315,14 @@ func (w *Worker) handleTask(msg *nats.Msg) {
 	}
 
 	// Update step with result
-	if err := w.updateStepWithResult(ctx, result); err != nil {
+	if err := w.updateStepWithResult(ctx, task.RunID, result); err != nil {
 		log.Printf("Failed to update step with result: %v", err)
 		msg.Nak()
 		return
 	}
 
 	// Publish result
-	if err := w.publishResult(ctx, result); err != nil {
+	if err := w.publishResult(ctx, task.RunID, result); err != nil {
 		log.Printf("Failed to publish result: %v", err)
 		msg.Nak()
 		return
@@ -181,7 +331,22 @@ func (w *Worker) handleTask(msg *nats.Msg) {
 	msg.Ack()
 }
 
-func (w *Worker) executeTask(ctx context.Context, task *Task) (*TaskResult, error) {
+// sendToDLQ republishes a poison message (one that has exhausted its
+// consumer's MaxDeliver attempts) to the priority's DLQ subject and acks
+// the original so it stops being redelivered.
+func (w *Worker) sendToDLQ(priority bus.Priority, msg *nats.Msg) {
+	log.Printf("Task on %s exceeded max deliveries, routing to DLQ", priority)
+
+	if _, err := w.js.Publish(bus.DLQSubject(priority), msg.Data); err != nil {
+		log.Printf("Failed to publish to DLQ: %v", err)
+		msg.NakWithDelay(30 * time.Second)
+		return
+	}
+
+	msg.Ack()
+}
+
+func (w *Worker) executeTask(ctx context.Context, task *Task, logs LogSink) (*TaskResult, error) {
 	executor, exists := w.executors[task.Node.Type]
 	if !exists {
 		return nil, fmt.Errorf("no executor for node type %s", task.Node.Type)
@@ -195,7 +360,11 @@ func (w *Worker) executeTask(ctx context.Context, task *Task) (*TaskResult, erro
 
 	var lastErr error
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		result, err := executor.Execute(ctx, task)
+		if attempt > 1 {
+			logs.WriteLine(ctx, task.ID, fmt.Sprintf("retrying (attempt %d/%d) after: %v", attempt, maxRetries, lastErr))
+		}
+
+		result, err := executor.Execute(ctx, task, logs)
 		if err == nil {
 			return result, nil
 		}
@@ -216,7 +385,7 @@ func (w *Worker) executeTask(ctx context.Context, task *Task) (*TaskResult, erro
 	return nil, fmt.Errorf("task failed after %d attempts: %w", maxRetries, lastErr)
 }
 
-func (w *Worker) updateStepStatus(ctx context.Context, stepID uuid.UUID, status StepStatus, workerID string) error {
+func (w *Worker) updateStepStatus(ctx context.Context, runID, stepID uuid.UUID, status StepStatus, workerID string) error {
 	var startedAt *time.Time
 	if status == StepStatusRunning {
 		now := time.Now()
@@ -224,11 +393,57 @@ func (w *Worker) updateStepStatus(ctx context.Context, stepID uuid.UUID, status
 	}
 
 	query := `UPDATE step_run SET status = $1, worker_id = $2, started_at = $3 WHERE id = $4`
-	_, err := w.db.ExecContext(ctx, query, status, workerID, startedAt, stepID)
+	if _, err := w.db.ExecContext(ctx, query, status, workerID, startedAt, stepID); err != nil {
+		return err
+	}
+
+	if err := w.publishStepEvent(ctx, runID, stepID, map[string]interface{}{
+		"status":    status,
+		"worker_id": workerID,
+	}); err != nil {
+		log.Printf("Failed to publish step.updated event: %v", err)
+	}
+
+	return nil
+}
+
+// publishStepEvent mirrors ControlPlane.publishEvent for the step updates
+// the worker makes directly, so that SSE subscribers see step transitions
+// without waiting on the control plane to poll.
+func (w *Worker) publishStepEvent(ctx context.Context, runID, stepID uuid.UUID, payload interface{}) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event payload: %w", err)
+	}
+
+	evt := &Event{
+		ID:         uuid.New(),
+		Type:       EventTypeStepUpdated,
+		ObjectType: "step_run",
+		ObjectID:   stepID,
+		RunID:      runID,
+		CreatedAt:  time.Now(),
+		Payload:    payloadJSON,
+	}
+
+	query := `INSERT INTO workflow_event (id, type, object_type, object_id, run_id, payload, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	if _, err := w.db.ExecContext(ctx, query,
+		evt.ID, evt.Type, evt.ObjectType, evt.ObjectID, evt.RunID, evt.Payload, evt.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to persist event: %w", err)
+	}
+
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	_, err = w.js.Publish(eventSubject(EventTypeStepUpdated), data)
 	return err
 }
 
-func (w *Worker) updateStepWithResult(ctx context.Context, result *TaskResult) error {
+func (w *Worker) updateStepWithResult(ctx context.Context, runID uuid.UUID, result *TaskResult) error {
 	now := time.Now()
 	
 	query := `UPDATE step_run SET 
@@ -236,20 +451,27 @@ func (w *Worker) updateStepWithResult(ctx context.Context, result *TaskResult) e
 			  tokens_prompt = $5, tokens_completion = $6
 			  WHERE id = $7`
 	
-	_, err := w.db.ExecContext(ctx, query,
+	if _, err := w.db.ExecContext(ctx, query,
 		result.Status, now, result.Error, result.CostCents,
 		result.TokensPrompt, result.TokensCompletion, result.TaskID,
-	)
-	return err
+	); err != nil {
+		return err
+	}
+
+	if err := w.publishStepEvent(ctx, runID, result.TaskID, result); err != nil {
+		log.Printf("Failed to publish step.updated event: %v", err)
+	}
+
+	return nil
 }
 
-func (w *Worker) publishResult(ctx context.Context, result *TaskResult) error {
+func (w *Worker) publishResult(ctx context.Context, runID uuid.UUID, result *TaskResult) error {
 	resultData, err := json.Marshal(result)
 	if err != nil {
 		return fmt.Errorf("failed to marshal result: %w", err)
 	}
 
-	_, err = w.js.Publish("agentflow.results", resultData)
+	_, err = w.js.Publish(bus.ResultSubject(runID.String()), resultData)
 	return err
 }
 
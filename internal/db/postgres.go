@@ -0,0 +1,100 @@
+// Package db wraps the Postgres connection AgentFlow's control plane and
+// workers share, adding resilient startup (retry with backoff until the
+// database is reachable) on top of the standard library's database/sql.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/Siddhant-K-code/agentflow-infrastructure/internal/config"
+)
+
+// PostgresDB is a thin wrapper around *sql.DB so callers (ControlPlane,
+// Worker) can depend on this package's type rather than database/sql
+// directly, matching how gorm.DB is used in cmd/server today.
+type PostgresDB struct {
+	*sql.DB
+}
+
+// BackoffConfig controls how WaitForReady retries a failed connection
+// attempt.
+type BackoffConfig struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// DefaultBackoff is used when callers don't need a custom retry curve.
+var DefaultBackoff = BackoffConfig{
+	Initial:    500 * time.Millisecond,
+	Max:        15 * time.Second,
+	Multiplier: 2,
+}
+
+// NewPostgresDB connects to cfg using the default backoff, blocking
+// until the database is reachable or ctx is done. It replaces the old
+// fail-fast "connect once or die" path Worker and ControlPlane used to
+// take.
+func NewPostgresDB(ctx context.Context, cfg *config.DatabaseConfig) (*PostgresDB, error) {
+	return WaitForReady(ctx, cfg, DefaultBackoff)
+}
+
+// WaitForReady retries connecting to cfg with exponential backoff and
+// jitter, logging a structured message on every attempt, until the
+// connection is pingable or ctx expires.
+func WaitForReady(ctx context.Context, cfg *config.DatabaseConfig, backoff BackoffConfig) (*PostgresDB, error) {
+	delay := backoff.Initial
+	attempt := 0
+
+	for {
+		attempt++
+
+		sqlDB, err := sql.Open("postgres", cfg.URL)
+		if err == nil {
+			pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			err = sqlDB.PingContext(pingCtx)
+			cancel()
+		}
+
+		if err == nil {
+			log.Printf("event=db_connect attempt=%d status=ok", attempt)
+			return &PostgresDB{DB: sqlDB}, nil
+		}
+
+		log.Printf("event=db_connect attempt=%d status=retrying next_delay=%s error=%q", attempt, delay, err)
+
+		jitter := time.Duration(rand.Int63n(int64(delay) / 2))
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to connect to database after %d attempts: %w", attempt, ctx.Err())
+		case <-time.After(delay + jitter):
+		}
+
+		delay = time.Duration(float64(delay) * backoff.Multiplier)
+		if delay > backoff.Max {
+			delay = backoff.Max
+		}
+	}
+}
+
+// TryAdvisoryLock attempts to acquire a Postgres advisory lock keyed by
+// key, returning whether it was acquired. Migration bootstrapping uses
+// this so multiple replicas starting concurrently don't race on DDL.
+func (p *PostgresDB) TryAdvisoryLock(ctx context.Context, key int64) (bool, error) {
+	var acquired bool
+	err := p.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired)
+	return acquired, err
+}
+
+// AdvisoryUnlock releases a lock taken by TryAdvisoryLock.
+func (p *PostgresDB) AdvisoryUnlock(ctx context.Context, key int64) error {
+	_, err := p.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, key)
+	return err
+}